@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// messageFrames controls how many ticks a command's feedback lingers in
+// the ui before render stops drawing it.
+const messageFrames = 6
+
+// uiState carries transient feedback from the `:`-prompt back up to
+// render, separate from options so a failed command never corrupts the
+// running config.
+type uiState struct {
+	message   string
+	isErr     bool
+	expiresAt int
+}
+
+func (u *uiState) info(frame int, format string, args ...interface{}) {
+	u.message = fmt.Sprintf(format, args...)
+	u.isErr = false
+	u.expiresAt = frame + messageFrames
+}
+
+func (u *uiState) errorf(frame int, format string, args ...interface{}) {
+	u.message = fmt.Sprintf(format, args...)
+	u.isErr = true
+	u.expiresAt = frame + messageFrames
+}
+
+func (u *uiState) visible(frame int) bool {
+	return u.message != "" && frame <= u.expiresAt
+}
+
+// execCommand parses and runs a single `:`-prompt command, mutating cfg,
+// ticker and ctl.sortBy in place and leaving feedback on ui for render to
+// draw. Unknown commands and bad arguments are reported through ui rather
+// than returned, since the caller has nowhere to put an error but there.
+func execCommand(raw string, cfg *options, ticker *time.Ticker, ctl *controller, ui *uiState, frame int) {
+	fields := strings.Fields(raw)
+	if len(fields) == 0 {
+		return
+	}
+	name, args := fields[0], fields[1:]
+
+	switch name {
+	case "tick":
+		if len(args) != 1 {
+			ui.errorf(frame, "usage: :tick <duration>")
+			return
+		}
+		d, err := time.ParseDuration(args[0])
+		if err != nil || d <= 0 {
+			ui.errorf(frame, "bad duration %q", args[0])
+			return
+		}
+		cfg.tick = d
+		ticker.Reset(d)
+		ui.info(frame, "tick set to %s", d)
+
+	case "lanes":
+		if len(args) != 1 {
+			ui.errorf(frame, "usage: :lanes <n>")
+			return
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n <= 0 {
+			ui.errorf(frame, "bad lane count %q", args[0])
+			return
+		}
+		cfg.maxLanes = n
+		ui.info(frame, "max lanes set to %d", n)
+
+	case "maluca":
+		on, ok := parseOnOff(args)
+		if !ok {
+			ui.errorf(frame, "usage: :maluca on|off")
+			return
+		}
+		cfg.malucaMode = on
+		ui.info(frame, "modo corrida maluca: %v", on)
+
+	case "ascii":
+		cfg.useEmoji = false
+		ui.info(frame, "switched to ascii cars")
+
+	case "emoji":
+		cfg.useEmoji = true
+		ui.info(frame, "switched to emoji cars")
+
+	case "kill":
+		signalPidCmd(args, syscall.SIGTERM, ui, frame)
+
+	case "renice":
+		reniceCmd(args, ui, frame)
+
+	case "sort":
+		if len(args) != 1 {
+			ui.errorf(frame, "usage: :sort cpu|mem|pid|name")
+			return
+		}
+		sk, ok := parseSortKey(args[0])
+		if !ok {
+			ui.errorf(frame, "unknown sort key %q", args[0])
+			return
+		}
+		ctl.sortBy = sk
+		ui.info(frame, "sorting by %s", sk)
+
+	default:
+		ui.errorf(frame, "unknown command %q", name)
+	}
+}
+
+func parseOnOff(args []string) (bool, bool) {
+	if len(args) != 1 {
+		return false, false
+	}
+	switch args[0] {
+	case "on":
+		return true, true
+	case "off":
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+func signalPidCmd(args []string, sig syscall.Signal, ui *uiState, frame int) {
+	if len(args) != 1 {
+		ui.errorf(frame, "usage: :kill <pid>")
+		return
+	}
+	pid, err := strconv.Atoi(args[0])
+	if err != nil {
+		ui.errorf(frame, "bad pid %q", args[0])
+		return
+	}
+	if err := syscall.Kill(pid, sig); err != nil {
+		ui.errorf(frame, "kill %d: %v", pid, err)
+		return
+	}
+	ui.info(frame, "sent %v to %d", sig, pid)
+}
+
+func reniceCmd(args []string, ui *uiState, frame int) {
+	if len(args) != 2 {
+		ui.errorf(frame, "usage: :renice <pid> <n>")
+		return
+	}
+	pid, err := strconv.Atoi(args[0])
+	if err != nil {
+		ui.errorf(frame, "bad pid %q", args[0])
+		return
+	}
+	prio, err := strconv.Atoi(args[1])
+	if err != nil {
+		ui.errorf(frame, "bad priority %q", args[1])
+		return
+	}
+	if err := syscall.Setpriority(syscall.PRIO_PROCESS, pid, prio); err != nil {
+		ui.errorf(frame, "renice %d: %v", pid, err)
+		return
+	}
+	ui.info(frame, "reniced %d to %d", pid, prio)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func newExecCommandFixture() (*options, *time.Ticker, *controller, *uiState) {
+	cfg := &options{
+		maxLanes:   10,
+		malucaMode: false,
+		tick:       600 * time.Millisecond,
+		useEmoji:   true,
+	}
+	ticker := time.NewTicker(cfg.tick)
+	var ctl controller
+	var ui uiState
+	return cfg, ticker, &ctl, &ui
+}
+
+func TestExecCommandBadTickLeavesCfgUnchanged(t *testing.T) {
+	cfg, ticker, ctl, ui := newExecCommandFixture()
+	defer ticker.Stop()
+	orig := cfg.tick
+
+	execCommand("tick notaduration", cfg, ticker, ctl, ui, 0)
+
+	if cfg.tick != orig {
+		t.Fatalf("cfg.tick = %v, want unchanged %v", cfg.tick, orig)
+	}
+	if !ui.isErr {
+		t.Fatal("expected ui.isErr after bad duration")
+	}
+}
+
+func TestExecCommandTickUpdatesCfg(t *testing.T) {
+	cfg, ticker, ctl, ui := newExecCommandFixture()
+	defer ticker.Stop()
+
+	execCommand("tick 200ms", cfg, ticker, ctl, ui, 0)
+
+	if cfg.tick != 200*time.Millisecond {
+		t.Fatalf("cfg.tick = %v, want 200ms", cfg.tick)
+	}
+	if ui.isErr {
+		t.Fatalf("unexpected error: %s", ui.message)
+	}
+}
+
+func TestExecCommandBadLaneCountLeavesCfgUnchanged(t *testing.T) {
+	cfg, ticker, ctl, ui := newExecCommandFixture()
+	defer ticker.Stop()
+	orig := cfg.maxLanes
+
+	execCommand("lanes -1", cfg, ticker, ctl, ui, 0)
+
+	if cfg.maxLanes != orig {
+		t.Fatalf("cfg.maxLanes = %d, want unchanged %d", cfg.maxLanes, orig)
+	}
+	if !ui.isErr {
+		t.Fatal("expected ui.isErr after bad lane count")
+	}
+
+	execCommand("lanes notanumber", cfg, ticker, ctl, ui, 0)
+	if cfg.maxLanes != orig {
+		t.Fatalf("cfg.maxLanes = %d, want unchanged %d", cfg.maxLanes, orig)
+	}
+	if !ui.isErr {
+		t.Fatal("expected ui.isErr after non-numeric lane count")
+	}
+}
+
+func TestExecCommandLanesUpdatesCfg(t *testing.T) {
+	cfg, ticker, ctl, ui := newExecCommandFixture()
+	defer ticker.Stop()
+
+	execCommand("lanes 15", cfg, ticker, ctl, ui, 0)
+
+	if cfg.maxLanes != 15 {
+		t.Fatalf("cfg.maxLanes = %d, want 15", cfg.maxLanes)
+	}
+	if ui.isErr {
+		t.Fatalf("unexpected error: %s", ui.message)
+	}
+}
+
+func TestExecCommandMalucaOnOff(t *testing.T) {
+	cfg, ticker, ctl, ui := newExecCommandFixture()
+	defer ticker.Stop()
+
+	execCommand("maluca on", cfg, ticker, ctl, ui, 0)
+	if !cfg.malucaMode {
+		t.Fatal("cfg.malucaMode = false, want true after :maluca on")
+	}
+
+	execCommand("maluca off", cfg, ticker, ctl, ui, 0)
+	if cfg.malucaMode {
+		t.Fatal("cfg.malucaMode = true, want false after :maluca off")
+	}
+
+	orig := cfg.malucaMode
+	execCommand("maluca sideways", cfg, ticker, ctl, ui, 0)
+	if cfg.malucaMode != orig {
+		t.Fatalf("cfg.malucaMode changed on bad arg, want unchanged %v", orig)
+	}
+	if !ui.isErr {
+		t.Fatal("expected ui.isErr after bad :maluca arg")
+	}
+}
+
+func TestExecCommandAsciiEmojiToggle(t *testing.T) {
+	cfg, ticker, ctl, ui := newExecCommandFixture()
+	defer ticker.Stop()
+
+	execCommand("ascii", cfg, ticker, ctl, ui, 0)
+	if cfg.useEmoji {
+		t.Fatal("cfg.useEmoji = true, want false after :ascii")
+	}
+
+	execCommand("emoji", cfg, ticker, ctl, ui, 0)
+	if !cfg.useEmoji {
+		t.Fatal("cfg.useEmoji = false, want true after :emoji")
+	}
+}
+
+func TestExecCommandSort(t *testing.T) {
+	cfg, ticker, ctl, ui := newExecCommandFixture()
+	defer ticker.Stop()
+
+	execCommand("sort mem", cfg, ticker, ctl, ui, 0)
+	if ctl.sortBy != sortMem {
+		t.Fatalf("ctl.sortBy = %v, want sortMem", ctl.sortBy)
+	}
+	if ui.isErr {
+		t.Fatalf("unexpected error: %s", ui.message)
+	}
+
+	orig := ctl.sortBy
+	execCommand("sort bogus", cfg, ticker, ctl, ui, 0)
+	if ctl.sortBy != orig {
+		t.Fatalf("ctl.sortBy changed on bad key, want unchanged %v", orig)
+	}
+	if !ui.isErr {
+		t.Fatal("expected ui.isErr after unknown sort key")
+	}
+}
+
+func TestExecCommandRenicheBadPriorityLeavesNoSideEffect(t *testing.T) {
+	cfg, ticker, ctl, ui := newExecCommandFixture()
+	defer ticker.Stop()
+
+	// The priority fails to parse before Setpriority is ever called, so
+	// this is safe to run without touching a real process (the pid here
+	// doesn't need to exist).
+	execCommand("renice 999999 notanumber", cfg, ticker, ctl, ui, 0)
+
+	if !ui.isErr {
+		t.Fatal("expected ui.isErr after bad priority")
+	}
+}
+
+func TestExecCommandRenicheBadArgCount(t *testing.T) {
+	cfg, ticker, ctl, ui := newExecCommandFixture()
+	defer ticker.Stop()
+
+	execCommand("renice 1", cfg, ticker, ctl, ui, 0)
+
+	if !ui.isErr {
+		t.Fatal("expected ui.isErr after missing renice argument")
+	}
+}
+
+func TestExecCommandKillBadPid(t *testing.T) {
+	cfg, ticker, ctl, ui := newExecCommandFixture()
+	defer ticker.Stop()
+
+	// Non-numeric pid fails to parse before syscall.Kill is ever called.
+	execCommand("kill notapid", cfg, ticker, ctl, ui, 0)
+
+	if !ui.isErr {
+		t.Fatal("expected ui.isErr after bad pid")
+	}
+}
+
+func TestExecCommandUnknown(t *testing.T) {
+	cfg, ticker, ctl, ui := newExecCommandFixture()
+	defer ticker.Stop()
+
+	execCommand("frobnicate", cfg, ticker, ctl, ui, 0)
+
+	if !ui.isErr {
+		t.Fatal("expected ui.isErr for unknown command")
+	}
+}
+
+func TestExecCommandEmptyIsNoop(t *testing.T) {
+	cfg, ticker, ctl, ui := newExecCommandFixture()
+	defer ticker.Stop()
+
+	execCommand("   ", cfg, ticker, ctl, ui, 0)
+
+	if ui.message != "" {
+		t.Fatalf("ui.message = %q, want empty for blank command", ui.message)
+	}
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"sort"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// termios mirrors linux's struct termios closely enough for the cbreak
+// dance below; we only ever flip a couple of lflag bits and the VMIN/VTIME
+// cc slots so a full x/sys/unix import isn't worth pulling in.
+type termios struct {
+	Iflag, Oflag, Cflag, Lflag uint32
+	Line                       uint8
+	Cc                         [32]uint8
+	Ispeed, Ospeed             uint32
+}
+
+const (
+	tcgets = 0x5401
+	tcsets = 0x5402
+
+	lflagICANON = 0x2
+	lflagECHO   = 0x8
+	ccVMIN      = 6
+	ccVTIME     = 5
+)
+
+func getTermios(fd uintptr) (*termios, error) {
+	var t termios
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, tcgets, uintptr(unsafe.Pointer(&t))); errno != 0 {
+		return nil, errno
+	}
+	return &t, nil
+}
+
+func setTermios(fd uintptr, t *termios) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, tcsets, uintptr(unsafe.Pointer(t))); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// enableRawMode puts stdin into cbreak mode (no line buffering, no echo,
+// one byte per read) so the event loop can react to a single keypress
+// instead of waiting on a newline. Returns a restore func to hand back to
+// the cooked mode the shell expects once we exit.
+func enableRawMode() (restore func(), err error) {
+	fd := os.Stdin.Fd()
+	orig, err := getTermios(fd)
+	if err != nil {
+		return nil, err
+	}
+	raw := *orig
+	raw.Lflag &^= lflagICANON | lflagECHO
+	raw.Cc[ccVMIN] = 1
+	raw.Cc[ccVTIME] = 0
+	if err := setTermios(fd, &raw); err != nil {
+		return nil, err
+	}
+	return func() { setTermios(fd, orig) }, nil
+}
+
+// startKeyReader streams stdin one byte at a time on a channel so the main
+// loop can select on it alongside the ticker without blocking a tick on
+// keyboard input.
+func startKeyReader() <-chan byte {
+	ch := make(chan byte, 16)
+	go func() {
+		r := bufio.NewReader(os.Stdin)
+		for {
+			b, err := r.ReadByte()
+			if err != nil {
+				close(ch)
+				return
+			}
+			ch <- b
+		}
+	}()
+	return ch
+}
+
+type sortKey int
+
+const (
+	sortCPU sortKey = iota
+	sortMem
+	sortPID
+	sortName
+)
+
+func (k sortKey) next() sortKey {
+	return (k + 1) % 4
+}
+
+func (k sortKey) String() string {
+	switch k {
+	case sortCPU:
+		return "cpu"
+	case sortMem:
+		return "mem"
+	case sortPID:
+		return "pid"
+	case sortName:
+		return "name"
+	default:
+		return "?"
+	}
+}
+
+func parseSortKey(s string) (sortKey, bool) {
+	switch strings.ToLower(s) {
+	case "cpu":
+		return sortCPU, true
+	case "mem":
+		return sortMem, true
+	case "pid":
+		return sortPID, true
+	case "name":
+		return sortName, true
+	default:
+		return 0, false
+	}
+}
+
+// controller owns the cursor, the active `/` filter and the sort key, and
+// turns raw keypresses into mutations of that state or signals sent to the
+// runner currently under the cursor. It is the dispatcher referenced by
+// the input tests: feed it bytes, inspect the resulting state.
+type controller struct {
+	cursor    int
+	filter    string
+	filtering bool
+	sortBy    sortKey
+
+	commanding     bool
+	commandBuf     string
+	pendingCommand string
+
+	// killFunc sends sig to pid; defaults to syscall.Kill when nil. Tests
+	// swap this in so 'K'/'x' can be exercised without sending a real
+	// signal to a real process.
+	killFunc func(pid int, sig syscall.Signal) error
+}
+
+// handleKey applies a single keypress against the lanes visible this
+// frame. lanes must already reflect the controller's own filter/sort so
+// the cursor lines up with what's on screen. Commands entered via `:` are
+// only staged on pendingCommand: running them needs cfg/ticker/ui, which
+// the caller pulls out and passes to execCommand once a frame.
+func (c *controller) handleKey(b byte, lanes []*runner) {
+	if c.commanding {
+		c.handleCommandKey(b)
+		return
+	}
+	if c.filtering {
+		c.handleFilterKey(b)
+		return
+	}
+
+	switch b {
+	case 'j', 'l':
+		c.moveCursor(1, len(lanes))
+	case 'k', 'h':
+		c.moveCursor(-1, len(lanes))
+	case 'g':
+		c.cursor = 0
+	case 'G':
+		c.cursor = len(lanes) - 1
+	case '/':
+		c.filtering = true
+		c.filter = ""
+	case ':':
+		c.commanding = true
+		c.commandBuf = ""
+	case 's':
+		c.sortBy = c.sortBy.next()
+	case 'K':
+		c.signalSelected(lanes, syscall.SIGTERM)
+	case 'x':
+		c.signalSelected(lanes, syscall.SIGKILL)
+	}
+	c.clampCursor(len(lanes))
+}
+
+func (c *controller) handleCommandKey(b byte) {
+	switch b {
+	case '\r', '\n':
+		c.pendingCommand = c.commandBuf
+		c.commanding = false
+		c.commandBuf = ""
+	case 27: // Esc cancels without running anything
+		c.commanding = false
+		c.commandBuf = ""
+	case 127, 8:
+		if len(c.commandBuf) > 0 {
+			c.commandBuf = c.commandBuf[:len(c.commandBuf)-1]
+		}
+	default:
+		if b >= 32 && b < 127 {
+			c.commandBuf += string(b)
+		}
+	}
+}
+
+func (c *controller) handleFilterKey(b byte) {
+	switch b {
+	case '\r', '\n', 27: // Enter or Esc both leave filter-entry mode
+		c.filtering = false
+	case 127, 8: // backspace (DEL or BS)
+		if len(c.filter) > 0 {
+			c.filter = c.filter[:len(c.filter)-1]
+		}
+	default:
+		if b >= 32 && b < 127 {
+			c.filter += string(b)
+		}
+	}
+}
+
+func (c *controller) moveCursor(delta, n int) {
+	if n == 0 {
+		c.cursor = 0
+		return
+	}
+	c.cursor += delta
+	c.clampCursor(n)
+}
+
+func (c *controller) clampCursor(n int) {
+	if n == 0 {
+		c.cursor = 0
+		return
+	}
+	if c.cursor < 0 {
+		c.cursor = 0
+	}
+	if c.cursor > n-1 {
+		c.cursor = n - 1
+	}
+}
+
+func (c *controller) selected(lanes []*runner) *runner {
+	if c.cursor < 0 || c.cursor >= len(lanes) {
+		return nil
+	}
+	return lanes[c.cursor]
+}
+
+func (c *controller) signalSelected(lanes []*runner, sig syscall.Signal) {
+	r := c.selected(lanes)
+	if r == nil {
+		return
+	}
+	kill := c.killFunc
+	if kill == nil {
+		kill = syscall.Kill
+	}
+	_ = kill(r.pid, sig)
+}
+
+// filterLanes drops runners whose name doesn't contain the active filter
+// substring (case-insensitive), leaving order untouched for sortLanes to
+// work on afterwards.
+func filterLanes(all []*runner, filter string) []*runner {
+	if filter == "" {
+		return all
+	}
+	needle := strings.ToLower(filter)
+	out := all[:0:0]
+	for _, r := range all {
+		if strings.Contains(strings.ToLower(r.name), needle) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// sortLanes reorders runners in place per the active sort key, mirroring
+// the tie-break rule pickLanes already used for CPU (PID asc).
+func sortLanes(lanes []*runner, by sortKey) {
+	sort.Slice(lanes, func(i, j int) bool {
+		a, b := lanes[i], lanes[j]
+		switch by {
+		case sortMem:
+			if a.mem == b.mem {
+				return a.pid < b.pid
+			}
+			return a.mem > b.mem
+		case sortPID:
+			return a.pid < b.pid
+		case sortName:
+			if a.name == b.name {
+				return a.pid < b.pid
+			}
+			return a.name < b.name
+		default: // sortCPU
+			if a.cpu == b.cpu {
+				return a.pid < b.pid
+			}
+			return a.cpu > b.cpu
+		}
+	})
+}
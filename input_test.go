@@ -0,0 +1,250 @@
+package main
+
+import (
+	"syscall"
+	"testing"
+)
+
+func lanesWithPIDs(pids ...int) []*runner {
+	lanes := make([]*runner, len(pids))
+	for i, pid := range pids {
+		lanes[i] = &runner{pid: pid, name: "runner"}
+	}
+	return lanes
+}
+
+func TestControllerHandleKeyMovesCursor(t *testing.T) {
+	var c controller
+	lanes := lanesWithPIDs(1, 2, 3)
+
+	c.handleKey('j', lanes)
+	if c.cursor != 1 {
+		t.Fatalf("cursor after j = %d, want 1", c.cursor)
+	}
+	c.handleKey('l', lanes)
+	if c.cursor != 2 {
+		t.Fatalf("cursor after l = %d, want 2", c.cursor)
+	}
+	c.handleKey('j', lanes) // already at the last lane, clamp
+	if c.cursor != 2 {
+		t.Fatalf("cursor after clamp = %d, want 2", c.cursor)
+	}
+	c.handleKey('k', lanes)
+	if c.cursor != 1 {
+		t.Fatalf("cursor after k = %d, want 1", c.cursor)
+	}
+	c.handleKey('g', lanes)
+	if c.cursor != 0 {
+		t.Fatalf("cursor after g = %d, want 0", c.cursor)
+	}
+	c.handleKey('G', lanes)
+	if c.cursor != len(lanes)-1 {
+		t.Fatalf("cursor after G = %d, want %d", c.cursor, len(lanes)-1)
+	}
+}
+
+func TestControllerHandleKeySort(t *testing.T) {
+	var c controller
+	lanes := lanesWithPIDs(1)
+
+	if c.sortBy != sortCPU {
+		t.Fatalf("initial sortBy = %v, want sortCPU", c.sortBy)
+	}
+	c.handleKey('s', lanes)
+	if c.sortBy != sortMem {
+		t.Fatalf("sortBy after one s = %v, want sortMem", c.sortBy)
+	}
+	c.handleKey('s', lanes)
+	c.handleKey('s', lanes)
+	c.handleKey('s', lanes)
+	if c.sortBy != sortCPU {
+		t.Fatalf("sortBy after wraparound = %v, want sortCPU", c.sortBy)
+	}
+}
+
+func TestControllerHandleKeyEntersFilterMode(t *testing.T) {
+	var c controller
+	lanes := lanesWithPIDs(1, 2)
+
+	c.handleKey('/', lanes)
+	if !c.filtering {
+		t.Fatal("expected filtering to be true after '/'")
+	}
+	for _, b := range []byte("chr") {
+		c.handleKey(b, lanes)
+	}
+	if c.filter != "chr" {
+		t.Fatalf("filter = %q, want %q", c.filter, "chr")
+	}
+	c.handleKey(127, lanes) // backspace
+	if c.filter != "ch" {
+		t.Fatalf("filter after backspace = %q, want %q", c.filter, "ch")
+	}
+	c.handleKey('\r', lanes)
+	if c.filtering {
+		t.Fatal("expected filtering to be false after Enter")
+	}
+}
+
+func TestControllerHandleKeyEscCancelsFilter(t *testing.T) {
+	var c controller
+	c.filtering = true
+	c.filter = "abc"
+
+	c.handleKey(27, nil)
+	if c.filtering {
+		t.Fatal("expected filtering to be false after Esc")
+	}
+	if c.filter != "abc" {
+		t.Fatalf("filter after Esc = %q, want unchanged %q", c.filter, "abc")
+	}
+}
+
+func TestControllerHandleKeyEntersCommandMode(t *testing.T) {
+	var c controller
+	lanes := lanesWithPIDs(1)
+
+	c.handleKey(':', lanes)
+	if !c.commanding {
+		t.Fatal("expected commanding to be true after ':'")
+	}
+	for _, b := range []byte("lanes 5") {
+		c.handleKey(b, lanes)
+	}
+	c.handleKey('\r', lanes)
+	if c.commanding {
+		t.Fatal("expected commanding to be false after Enter")
+	}
+	if c.pendingCommand != "lanes 5" {
+		t.Fatalf("pendingCommand = %q, want %q", c.pendingCommand, "lanes 5")
+	}
+}
+
+func TestControllerHandleCommandKeyEscDiscards(t *testing.T) {
+	var c controller
+	c.commanding = true
+	c.commandBuf = "kill 1"
+
+	c.handleKey(27, nil)
+	if c.commanding {
+		t.Fatal("expected commanding to be false after Esc")
+	}
+	if c.pendingCommand != "" {
+		t.Fatalf("pendingCommand = %q, want empty after Esc", c.pendingCommand)
+	}
+}
+
+func TestFilterLanes(t *testing.T) {
+	lanes := []*runner{
+		{pid: 1, name: "chrome"},
+		{pid: 2, name: "bash"},
+		{pid: 3, name: "Chromium"},
+	}
+
+	got := filterLanes(lanes, "chr")
+	if len(got) != 2 {
+		t.Fatalf("filterLanes returned %d lanes, want 2", len(got))
+	}
+	for _, r := range got {
+		if r.name != "chrome" && r.name != "Chromium" {
+			t.Fatalf("unexpected lane %q survived filter", r.name)
+		}
+	}
+
+	if got := filterLanes(lanes, ""); len(got) != len(lanes) {
+		t.Fatalf("empty filter should return all lanes, got %d", len(got))
+	}
+}
+
+func TestSortLanes(t *testing.T) {
+	lanes := []*runner{
+		{pid: 2, name: "b", cpu: 10, mem: 5},
+		{pid: 1, name: "a", cpu: 10, mem: 20},
+		{pid: 3, name: "a", cpu: 30, mem: 1},
+	}
+
+	sortLanes(lanes, sortCPU)
+	if lanes[0].pid != 3 {
+		t.Fatalf("sortCPU: lanes[0].pid = %d, want 3 (highest cpu)", lanes[0].pid)
+	}
+
+	sortLanes(lanes, sortMem)
+	if lanes[0].pid != 1 {
+		t.Fatalf("sortMem: lanes[0].pid = %d, want 1 (highest mem)", lanes[0].pid)
+	}
+
+	sortLanes(lanes, sortPID)
+	for i := 1; i < len(lanes); i++ {
+		if lanes[i-1].pid > lanes[i].pid {
+			t.Fatalf("sortPID: lanes not ascending at index %d", i)
+		}
+	}
+
+	sortLanes(lanes, sortName)
+	if lanes[0].name != "a" || lanes[1].name != "a" || lanes[0].pid > lanes[1].pid {
+		t.Fatalf("sortName: want [a(pid1) a(pid3) b(pid2)] tiebroken by pid, got %+v", lanes)
+	}
+}
+
+// recordedKill captures the (pid, sig) pairs a controller's killFunc was
+// called with, so 'K'/'x' can be asserted against without touching a real
+// process.
+type recordedKill struct {
+	pid int
+	sig syscall.Signal
+}
+
+func TestControllerHandleKeySendsSIGTERMOnK(t *testing.T) {
+	var c controller
+	var calls []recordedKill
+	c.killFunc = func(pid int, sig syscall.Signal) error {
+		calls = append(calls, recordedKill{pid, sig})
+		return nil
+	}
+	lanes := lanesWithPIDs(10, 20, 30)
+	c.cursor = 1 // pid 20
+
+	c.handleKey('K', lanes)
+
+	if len(calls) != 1 {
+		t.Fatalf("killFunc called %d times, want 1", len(calls))
+	}
+	if calls[0] != (recordedKill{pid: 20, sig: syscall.SIGTERM}) {
+		t.Fatalf("got %+v, want {pid:20 sig:SIGTERM}", calls[0])
+	}
+}
+
+func TestControllerHandleKeySendsSIGKILLOnX(t *testing.T) {
+	var c controller
+	var calls []recordedKill
+	c.killFunc = func(pid int, sig syscall.Signal) error {
+		calls = append(calls, recordedKill{pid, sig})
+		return nil
+	}
+	lanes := lanesWithPIDs(10, 20, 30)
+	c.cursor = 2 // pid 30
+
+	c.handleKey('x', lanes)
+
+	if len(calls) != 1 {
+		t.Fatalf("killFunc called %d times, want 1", len(calls))
+	}
+	if calls[0] != (recordedKill{pid: 30, sig: syscall.SIGKILL}) {
+		t.Fatalf("got %+v, want {pid:30 sig:SIGKILL}", calls[0])
+	}
+}
+
+func TestControllerHandleKeyKillNoSelectionIsNoop(t *testing.T) {
+	var c controller
+	var calls []recordedKill
+	c.killFunc = func(pid int, sig syscall.Signal) error {
+		calls = append(calls, recordedKill{pid, sig})
+		return nil
+	}
+
+	c.handleKey('K', nil) // no lanes, nothing under the cursor
+
+	if len(calls) != 0 {
+		t.Fatalf("killFunc called %d times with no lanes, want 0", len(calls))
+	}
+}
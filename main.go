@@ -1,41 +1,45 @@
 package main
 
 import (
-	"bufio"
-	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
 	"os"
-	"os/exec"
 	"os/signal"
-	"sort"
 	"strconv"
 	"strings"
 	"time"
 )
 
 type processSample struct {
-	pid     int
-	cpu     float64
-	mem     float64
-	state   string
-	command string
+	pid          int
+	cpu          float64
+	mem          float64
+	state        string
+	command      string
+	threads      int
+	ioReadBytes  uint64
+	ioWriteBytes uint64
 }
 
 type runner struct {
-	pid       int
-	name      string
-	pos       float64
-	velocity  float64
-	cpu       float64
-	mem       float64
-	state     string
-	lastSeen  time.Time
-	deadAt    time.Time
-	status    runnerStatus
-	maluca    bool
-	obstacleS bool
+	pid          int
+	name         string
+	pos          float64
+	velocity     float64
+	cpu          float64
+	mem          float64
+	state        string
+	lastSeen     time.Time
+	deadAt       time.Time
+	status       runnerStatus
+	maluca       bool
+	obstacleS    bool
+	threads      int
+	ioReadBytes  uint64
+	ioWriteBytes uint64
 }
 
 type runnerStatus int
@@ -52,18 +56,66 @@ type options struct {
 	malucaMode bool
 	tick       time.Duration
 	useEmoji   bool
+	theme      Theme
+	source     ProcessSource
+	rng        *rand.Rand
 }
 
 func main() {
+	seed := time.Now().UnixNano()
+	source := sourceByName(flagValue("--source"))
+
+	if flagValue("--replay") != "" && flagValue("--record") != "" {
+		fmt.Println("--replay and --record are mutually exclusive; ignoring --record")
+	}
+
+	var closeRecording func() error
+	switch {
+	case flagValue("--replay") != "":
+		rs, replaySeed, err := newReplaySource(flagValue("--replay"))
+		if err != nil {
+			fmt.Println("failed to open replay file:", err)
+			os.Exit(1)
+		}
+		source = rs
+		seed = replaySeed
+	case flagValue("--record") != "":
+		rs, closeFn, err := newRecordingSource(source, flagValue("--record"), seed)
+		if err != nil {
+			fmt.Println("failed to open record file:", err)
+			os.Exit(1)
+		}
+		source = rs
+		closeRecording = closeFn
+	}
+
+	theme := themeByName(flagValue("--theme"))
 	cfg := options{
 		maxLanes:   10,
 		malucaMode: hasFlag("--maluca") || hasFlag("-m"),
 		tick:       600 * time.Millisecond,
-		useEmoji:   !hasFlag("--ascii"),
+		// --theme=ascii is the font/terminal-compat fallback, so asking
+		// for it also turns off emoji cars rather than leaving pickSprite
+		// to draw a broken emoji on top of otherwise-plain chrome.
+		useEmoji: !hasFlag("--ascii") && theme.Name() != "ascii",
+		theme:    theme,
+		source:   source,
+		rng:      rand.New(rand.NewSource(seed)),
+	}
+	if flagValue("--replay") != "" {
+		// pacing now comes from replaySource sleeping out the recorded
+		// cadence, not from our own ticker, so keep this as tight as
+		// time.Ticker allows.
+		cfg.tick = time.Millisecond
+	}
+	if closeRecording != nil {
+		defer func() {
+			if err := closeRecording(); err != nil {
+				fmt.Println("failed to finalize recording:", err)
+			}
+		}()
 	}
 
-	rand.Seed(time.Now().UnixNano())
-
 	runners := map[int]*runner{}
 	ticker := time.NewTicker(cfg.tick)
 	defer ticker.Stop()
@@ -73,16 +125,53 @@ func main() {
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt)
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	restoreTerm, err := enableRawMode()
+	if err == nil {
+		defer restoreTerm()
+	}
+	keys := startKeyReader()
+
+	var ctl controller
+	var ui uiState
+	var lastLanes []*runner
+
 	frame := 0
 	for {
 		select {
 		case <-stop:
+			cancel()
 			clearScreen()
 			return
 		default:
 		}
 
-		samples, err := pollProcesses()
+	drainKeys:
+		for {
+			select {
+			case b, ok := <-keys:
+				if !ok {
+					break drainKeys
+				}
+				ctl.handleKey(b, lastLanes)
+			default:
+				break drainKeys
+			}
+		}
+
+		if ctl.pendingCommand != "" {
+			execCommand(ctl.pendingCommand, &cfg, ticker, &ctl, &ui, frame)
+			ctl.pendingCommand = ""
+		}
+
+		samples, err := cfg.source.Poll(ctx)
+		if errors.Is(err, errReplayDone) {
+			cancel()
+			clearScreen()
+			return
+		}
 		if err != nil {
 			fmt.Println("failed to read processes:", err)
 			time.Sleep(2 * time.Second)
@@ -93,7 +182,7 @@ func main() {
 		for _, s := range samples {
 			r, ok := runners[s.pid]
 			if !ok {
-				r = &runner{pid: s.pid, pos: float64(rand.Intn(5))}
+				r = &runner{pid: s.pid, pos: float64(cfg.rng.Intn(5))}
 				runners[s.pid] = r
 			}
 			r.lastSeen = now
@@ -101,6 +190,9 @@ func main() {
 			r.cpu = s.cpu
 			r.mem = s.mem
 			r.state = s.state
+			r.threads = s.threads
+			r.ioReadBytes = s.ioReadBytes
+			r.ioWriteBytes = s.ioWriteBytes
 			r.maluca = cfg.malucaMode && isChrome(r.name)
 			r.status = deriveStatus(r)
 			r.velocity = computeVelocity(r)
@@ -119,13 +211,14 @@ func main() {
 			}
 		}
 
-		lanes := pickLanes(runners, cfg.maxLanes)
+		lanes := pickLanes(runners, cfg.maxLanes, ctl.filter, ctl.sortBy)
 		width := trackWidth()
-		obstacles := spawnObstacles(lanes)
+		obstacles := spawnObstacles(lanes, cfg.rng)
 
 		updatePositions(lanes, obstacles, cfg.tick.Seconds())
 
-		render(lanes, obstacles, width, frame, cfg)
+		render(lanes, obstacles, width, frame, cfg, &ctl, &ui)
+		lastLanes = lanes
 		frame++
 
 		<-ticker.C
@@ -141,46 +234,15 @@ func hasFlag(flag string) bool {
 	return false
 }
 
-func pollProcesses() ([]processSample, error) {
-	cmd := exec.Command("ps", "-axo", "pid,pcpu,pmem,state,comm")
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	if err := cmd.Run(); err != nil {
-		return nil, err
-	}
-
-	scanner := bufio.NewScanner(&out)
-	var samples []processSample
-	first := true
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if first {
-			first = false
-			continue
-		}
-		fields := strings.Fields(line)
-		if len(fields) < 5 {
-			continue
-		}
-
-		pid, err := strconv.Atoi(fields[0])
-		if err != nil {
-			continue
+// flagValue returns the value of a `--name=value` flag, or "" if absent.
+func flagValue(name string) string {
+	prefix := name + "="
+	for _, arg := range os.Args[1:] {
+		if strings.HasPrefix(arg, prefix) {
+			return strings.TrimPrefix(arg, prefix)
 		}
-		cpu, _ := strconv.ParseFloat(fields[1], 64)
-		mem, _ := strconv.ParseFloat(fields[2], 64)
-		state := fields[3]
-		command := strings.Join(fields[4:], " ")
-		samples = append(samples, processSample{
-			pid:     pid,
-			cpu:     cpu,
-			mem:     mem,
-			state:   state,
-			command: command,
-		})
-	}
-
-	return samples, scanner.Err()
+	}
+	return ""
 }
 
 func deriveStatus(r *runner) runnerStatus {
@@ -220,18 +282,13 @@ func computeVelocity(r *runner) float64 {
 	return math.Max(0.1, base-weightPenalty)
 }
 
-func pickLanes(all map[int]*runner, max int) []*runner {
+func pickLanes(all map[int]*runner, max int, filter string, by sortKey) []*runner {
 	var list []*runner
 	for _, r := range all {
 		list = append(list, r)
 	}
-	// stable-ish: CPU desc then PID asc
-	sort.Slice(list, func(i, j int) bool {
-		if list[i].cpu == list[j].cpu {
-			return list[i].pid < list[j].pid
-		}
-		return list[i].cpu > list[j].cpu
-	})
+	list = filterLanes(list, filter)
+	sortLanes(list, by)
 	if len(list) > max {
 		list = list[:max]
 	}
@@ -252,7 +309,7 @@ func trackWidth() int {
 	return columns - 40
 }
 
-func spawnObstacles(lanes []*runner) map[int]struct{} {
+func spawnObstacles(lanes []*runner, rng *rand.Rand) map[int]struct{} {
 	obstacles := map[int]struct{}{}
 	if len(lanes) == 0 {
 		return obstacles
@@ -272,7 +329,7 @@ func spawnObstacles(lanes []*runner) map[int]struct{} {
 		count = 2
 	}
 	for i := 0; i < count; i++ {
-		obstacles[5+rand.Intn(trackWidth()-10)] = struct{}{}
+		obstacles[5+rng.Intn(trackWidth()-10)] = struct{}{}
 	}
 	return obstacles
 }
@@ -302,48 +359,57 @@ func updatePositions(lanes []*runner, obstacles map[int]struct{}, delta float64)
 	}
 }
 
-func render(lanes []*runner, obstacles map[int]struct{}, width int, frame int, cfg options) {
+// render draws one frame to the terminal. The actual frame assembly lives
+// in renderFrame so tests can check the string directly instead of
+// capturing stdout.
+func render(lanes []*runner, obstacles map[int]struct{}, width int, frame int, cfg options, ctl *controller, ui *uiState) {
+	fmt.Print(renderFrame(lanes, obstacles, width, frame, cfg, ctl, ui))
+}
+
+func renderFrame(lanes []*runner, obstacles map[int]struct{}, width int, frame int, cfg options, ctl *controller, ui *uiState) string {
+	cursor := ctl.cursor
 	var b strings.Builder
 	b.WriteString("\033[H\033[J")
 	finish := width - 2
 	header := fmt.Sprintf("HTop Gear â€” %d corredores vivos | modo corrida maluca: %v", len(lanes), cfg.malucaMode)
 
+	theme := cfg.theme
 	trackLines := make([]string, 0, len(lanes)*4)
 	for i, r := range lanes {
-		sprite := pickSprite(r, cfg)
+		sprite := pickSprite(r, cfg, theme)
 		carHeight := len(sprite)
 		midLine := carHeight / 2
 		laneClr := laneColor(i)
 		label := fmt.Sprintf("Lane %02d | %-16s CPU:%5.1f MEM:%5.1f %s", i+1, trimName(r.name), r.cpu, r.mem, coloredStatus(r))
+		if i == cursor {
+			label = colorize(label, "", attrUnderline)
+		}
 		for h := 0; h < carHeight; h++ {
-			line := make([]byte, width)
-			for j := range line {
-				line[j] = ' '
-			}
+			row := newRow(width)
 			offset := frame % 8
 			for pos := 4 + offset; pos < width-1; pos += 8 {
-				line[pos] = '-'
+				row[pos] = cell{glyph: theme.Dash()}
 			}
-			line[0] = '|'
-			line[finish] = '|'
+			row[0] = cell{glyph: theme.Wall()}
+			row[finish] = cell{glyph: theme.Finish(), attrs: []string{attrReverse}}
 			if h == midLine {
 				for pos := range obstacles {
 					if pos >= 0 && pos < width {
-						line[pos] = '#'
+						row[pos] = cell{glyph: theme.Obstacle()}
 					}
 				}
 				if r.obstacleS && int(r.pos) < width {
-					line[int(r.pos)] = '!'
+					row[int(r.pos)] = cell{glyph: "!", color: colorRed}
 				}
 			}
 
-			coloredCar := colorize(sprite[h], laneClr)
-			putSpriteLine(line, int(r.pos), coloredCar)
+			carAttrs := statusAttrs(r.status)
+			putSpriteCells(row, int(r.pos), sprite[h], laneClr, carAttrs)
 			prefix := ""
 			if h == 0 {
 				prefix = label
 			}
-			trackLines = append(trackLines, fmt.Sprintf("%-40s %s", prefix, string(line)))
+			trackLines = append(trackLines, fmt.Sprintf("%-40s %s", prefix, renderRow(row)))
 		}
 	}
 
@@ -378,10 +444,38 @@ func render(lanes []*runner, obstacles map[int]struct{}, width int, frame int, c
 		}
 	}
 
-	fmt.Print(b.String())
+	b.WriteString(promptLine(ctl, ui, frame))
+	b.WriteByte('\n')
+
+	return b.String()
 }
 
-func pickSprite(r *runner, cfg options) []string {
+// promptLine renders the single line at the bottom of the screen: the
+// live `:`/`/` entry buffer while one is open, otherwise the most recent
+// command feedback for as long as ui considers it visible.
+func promptLine(ctl *controller, ui *uiState, frame int) string {
+	switch {
+	case ctl.commanding:
+		return ":" + ctl.commandBuf
+	case ctl.filtering:
+		return "/" + ctl.filter
+	case ui.visible(frame):
+		clr := colorGreen
+		if ui.isErr {
+			clr = colorRed
+		}
+		return colorize(ui.message, clr)
+	default:
+		return ""
+	}
+}
+
+// pickSprite picks the sprite frames for a runner. Status-special frames
+// (dead/zombie/pit) are the same plain glyphs across every theme — what
+// sets statuses apart visually is the dim/blink attrs statusAttrs adds at
+// render time, not the glyph itself. Everything else defers to the
+// active theme, unless emoji mode is on, which wins regardless of theme.
+func pickSprite(r *runner, cfg options, th Theme) []string {
 	switch r.status {
 	case statusDead:
 		return []string{"X_X"}
@@ -392,72 +486,25 @@ func pickSprite(r *runner, cfg options) []string {
 	}
 
 	if cfg.useEmoji {
-		car := "ðŸŽï¸âž¡ï¸"
-		if r.mem > 12 {
-			car = "ðŸš›âž¡ï¸"
-		} else if r.mem > 6 {
-			car = "ðŸš™âž¡ï¸"
-		}
-		if r.maluca {
-			car = "[CHR]" + car
-		}
-		if r.cpu > 70 {
-			car += "ðŸ’¨ðŸ”¥"
-		}
-		return []string{car}
-	}
-
-	car := []string{
-		"___      ___        ::::::::::::::::::::::::       [_ _]    [_ _]   _",
-		"  /|  ___$________S_   | \\",
-		" / |-/        ____  [++| |+",
-		"<<<<<---<|  |>____O)<ooo>|",
-		" \\ |-\\___ ________ _[++| |+",
-		"  \\|    _$_      _S_   |_/  ",
-		"       [___]    [___]",
+		return emojiCar(r)
 	}
+	return th.Car(r)
+}
 
+func emojiCar(r *runner) []string {
+	car := "ðŸŽï¸âž¡ï¸"
 	if r.mem > 12 {
-		car[0] = "[P]" + car[0]
+		car = "ðŸš›âž¡ï¸"
 	} else if r.mem > 6 {
-		car[0] = "[+]" + car[0]
+		car = "ðŸš™âž¡ï¸"
 	}
 	if r.maluca {
-		car[0] = "[CHR]" + car[0]
+		car = "[CHR]" + car
 	}
 	if r.cpu > 70 {
-		car[len(car)-1] = car[len(car)-1] + ">>"
-	}
-	return car
-}
-
-func putSprite(line []byte, pos int, sprite string) {
-	if pos < 0 {
-		pos = 0
-	}
-	if pos >= len(line) {
-		pos = len(line) - 1
-	}
-	for i := 0; i < len(sprite) && pos+i < len(line); i++ {
-		line[pos+i] = sprite[i]
-	}
-}
-
-func putSpriteLine(line []byte, pos int, sprite string) {
-	if pos < 0 {
-		pos = 0
-	}
-	if pos >= len(line) {
-		pos = len(line) - 1
-	}
-	for i := 0; i < len(sprite) && pos+i < len(line); i++ {
-		ch := sprite[i]
-		if ch == '\\' && pos+i < len(line) {
-			line[pos+i] = '\\'
-			continue
-		}
-		line[pos+i] = ch
+		car += "ðŸ’¨ðŸ”¥"
 	}
+	return []string{car}
 }
 
 func buildScoreboard(lanes []*runner) []string {
@@ -517,18 +564,48 @@ const (
 	colorBrightBlue    = "\033[94m"
 	colorBrightMagenta = "\033[95m"
 	colorBrightCyan    = "\033[96m"
+
+	attrDim       = "\033[2m"
+	attrBlink     = "\033[5m"
+	attrUnderline = "\033[4m"
+	attrReverse   = "\033[7m"
 )
 
-func colorize(s, color string) string {
-	return color + s + colorReset
+// colorize wraps s in color plus any extra attrs (attrDim, attrBlink,
+// attrUnderline, attrReverse, ...), resetting after. color and attrs may
+// both be empty, in which case s is returned untouched rather than
+// wrapped in a no-op escape pair.
+func colorize(s, color string, attrs ...string) string {
+	prefix := color
+	for _, a := range attrs {
+		prefix += a
+	}
+	if prefix == "" {
+		return s
+	}
+	return prefix + s + colorReset
+}
+
+// statusAttrs are the ANSI attributes layered onto a runner's sprite to
+// make its status legible even when the glyphs themselves are shared
+// across themes: dead lanes dim out, zombies blink slowly.
+func statusAttrs(status runnerStatus) []string {
+	switch status {
+	case statusDead:
+		return []string{attrDim}
+	case statusZombie:
+		return []string{attrBlink}
+	default:
+		return nil
+	}
 }
 
 func coloredStatus(r *runner) string {
 	switch r.status {
 	case statusDead:
-		return colorize("X_X", colorGray)
+		return colorize("X_X", colorGray, attrDim)
 	case statusZombie:
-		return colorize("zombie", colorMagenta)
+		return colorize("zombie", colorMagenta, attrBlink)
 	case statusPitStop:
 		return colorize("pit", colorYellow)
 	default:
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	gopsutilprocess "github.com/shirou/gopsutil/v3/process"
+)
+
+// ProcessSource abstracts where a tick's process samples come from, so
+// the ps-shelling-out approach below can sit alongside faster, more
+// portable alternatives without main or render knowing the difference.
+type ProcessSource interface {
+	Poll(ctx context.Context) ([]processSample, error)
+}
+
+func sourceByName(name string) ProcessSource {
+	switch name {
+	case "proc":
+		return newProcSource()
+	case "gopsutil":
+		return gopsutilSource{}
+	case "ps", "":
+		return psSource{}
+	default:
+		return psSource{}
+	}
+}
+
+// psSource is the original implementation: shell out to `ps` and parse
+// its column output. Slow and a little fragile across `ps` variants, but
+// it's the one backend that needs nothing beyond a POSIX `ps` in $PATH.
+type psSource struct{}
+
+func (psSource) Poll(ctx context.Context) ([]processSample, error) {
+	cmd := exec.CommandContext(ctx, "ps", "-axo", "pid,pcpu,pmem,state,comm")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(&out)
+	var samples []processSample
+	first := true
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if first {
+			first = false
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		cpu, _ := strconv.ParseFloat(fields[1], 64)
+		mem, _ := strconv.ParseFloat(fields[2], 64)
+		state := fields[3]
+		command := strings.Join(fields[4:], " ")
+		samples = append(samples, processSample{
+			pid:     pid,
+			cpu:     cpu,
+			mem:     mem,
+			state:   state,
+			command: command,
+		})
+	}
+
+	return samples, scanner.Err()
+}
+
+// cpuJiffies is the utime+stime snapshot procSource needs to turn two
+// /proc/[pid]/stat reads into a %CPU figure, the same way `top` does.
+type cpuJiffies struct {
+	total uint64
+	at    time.Time
+}
+
+// procSource reads /proc directly instead of shelling out, computing
+// %CPU from jiffy deltas between ticks. Linux-only, which is fine: it's
+// an opt-in alternative to psSource, not a replacement.
+type procSource struct {
+	prev   map[int]cpuJiffies
+	clkTck float64
+}
+
+func newProcSource() *procSource {
+	return &procSource{
+		prev:   map[int]cpuJiffies{},
+		clkTck: 100, // USER_HZ is 100 on effectively every Linux build we target
+	}
+}
+
+func (p *procSource) Poll(ctx context.Context) ([]processSample, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	totalMemKB := systemMemKB()
+	seen := make(map[int]struct{}, len(entries))
+	var samples []processSample
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		sample, jiff, ok := p.readProcess(pid, now, totalMemKB)
+		if !ok {
+			continue
+		}
+		samples = append(samples, sample)
+		p.prev[pid] = jiff
+		seen[pid] = struct{}{}
+	}
+
+	for pid := range p.prev {
+		if _, ok := seen[pid]; !ok {
+			delete(p.prev, pid)
+		}
+	}
+
+	return samples, nil
+}
+
+func (p *procSource) readProcess(pid int, now time.Time, totalMemKB uint64) (processSample, cpuJiffies, bool) {
+	raw, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return processSample{}, cpuJiffies{}, false
+	}
+
+	// comm is wrapped in parens and may itself contain spaces or parens
+	// (e.g. kernel threads named "[kworker/...]"), so split on the last
+	// ')' rather than naive whitespace fields.
+	open := bytes.IndexByte(raw, '(')
+	closeParen := bytes.LastIndexByte(raw, ')')
+	if open < 0 || closeParen < open {
+		return processSample{}, cpuJiffies{}, false
+	}
+	comm := string(raw[open+1 : closeParen])
+	rest := strings.Fields(string(raw[closeParen+1:]))
+	// rest is stat's fields from state (3) onward; utime/stime/threads
+	// are fields 14, 15 and 20, i.e. rest[11], rest[12] and rest[17].
+	if len(rest) < 18 {
+		return processSample{}, cpuJiffies{}, false
+	}
+
+	state := rest[0]
+	utime, _ := strconv.ParseUint(rest[11], 10, 64)
+	stime, _ := strconv.ParseUint(rest[12], 10, 64)
+	threads, _ := strconv.Atoi(rest[17])
+
+	total := utime + stime
+	jiff := cpuJiffies{total: total, at: now}
+
+	var cpuPct float64
+	if prev, ok := p.prev[pid]; ok && total >= prev.total {
+		if elapsed := now.Sub(prev.at).Seconds(); elapsed > 0 {
+			cpuPct = float64(total-prev.total) / p.clkTck / elapsed * 100
+		}
+	}
+
+	readBytes, writeBytes := readIOBytes(pid)
+
+	return processSample{
+		pid:          pid,
+		cpu:          cpuPct,
+		mem:          readRSSPercent(pid, totalMemKB),
+		state:        state,
+		command:      comm,
+		threads:      threads,
+		ioReadBytes:  readBytes,
+		ioWriteBytes: writeBytes,
+	}, jiff, true
+}
+
+// readRSSPercent takes totalMemKB rather than calling systemMemKB itself:
+// MemTotal is identical for every process in a tick, so Poll reads
+// /proc/meminfo once per tick and passes it down instead of every process
+// re-opening and re-parsing the same file.
+func readRSSPercent(pid int, totalMemKB uint64) float64 {
+	if totalMemKB == 0 {
+		return 0
+	}
+	raw, err := os.ReadFile(fmt.Sprintf("/proc/%d/statm", pid))
+	if err != nil {
+		return 0
+	}
+	fields := strings.Fields(string(raw))
+	if len(fields) < 2 {
+		return 0
+	}
+	residentPages, _ := strconv.ParseUint(fields[1], 10, 64)
+	residentKB := residentPages * uint64(os.Getpagesize()) / 1024
+	return float64(residentKB) / float64(totalMemKB) * 100
+}
+
+func systemMemKB() uint64 {
+	raw, err := os.ReadFile("/proc/meminfo")
+	if err != nil {
+		return 0
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "MemTotal:") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return 0
+		}
+		v, _ := strconv.ParseUint(fields[1], 10, 64)
+		return v
+	}
+	return 0
+}
+
+// readIOBytes reads cumulative disk I/O for a process. /proc/[pid]/io is
+// root- or owner-only on most distros, so a permission error just means
+// zero values rather than a failed poll.
+func readIOBytes(pid int) (read, write uint64) {
+	raw, err := os.ReadFile(fmt.Sprintf("/proc/%d/io", pid))
+	if err != nil {
+		return 0, 0
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "read_bytes:"):
+			read, _ = strconv.ParseUint(strings.TrimSpace(strings.TrimPrefix(line, "read_bytes:")), 10, 64)
+		case strings.HasPrefix(line, "write_bytes:"):
+			write, _ = strconv.ParseUint(strings.TrimSpace(strings.TrimPrefix(line, "write_bytes:")), 10, 64)
+		}
+	}
+	return read, write
+}
+
+// gopsutilSource delegates to gopsutil for cross-platform coverage
+// (macOS, Windows, BSDs) at the cost of a third-party dependency.
+type gopsutilSource struct{}
+
+func (gopsutilSource) Poll(ctx context.Context) ([]processSample, error) {
+	procs, err := gopsutilprocess.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make([]processSample, 0, len(procs))
+	for _, proc := range procs {
+		name, err := proc.NameWithContext(ctx)
+		if err != nil {
+			continue
+		}
+		cpuPct, _ := proc.CPUPercentWithContext(ctx)
+		memPct, _ := proc.MemoryPercentWithContext(ctx)
+		threads, _ := proc.NumThreadsWithContext(ctx)
+
+		state := ""
+		if statuses, err := proc.StatusWithContext(ctx); err == nil && len(statuses) > 0 {
+			state = statuses[0]
+		}
+
+		var readBytes, writeBytes uint64
+		if io, err := proc.IOCountersWithContext(ctx); err == nil && io != nil {
+			readBytes = io.ReadBytes
+			writeBytes = io.WriteBytes
+		}
+
+		samples = append(samples, processSample{
+			pid:          int(proc.Pid),
+			cpu:          cpuPct,
+			mem:          float64(memPct),
+			state:        state,
+			command:      name,
+			threads:      int(threads),
+			ioReadBytes:  readBytes,
+			ioWriteBytes: writeBytes,
+		})
+	}
+
+	return samples, nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+// BenchmarkPsSource_Poll shells out to `ps` once per iteration, same as
+// psSource always has: the baseline the other two sources are meant to
+// beat on a box with a realistic process count.
+func BenchmarkPsSource_Poll(b *testing.B) {
+	ctx := context.Background()
+	s := psSource{}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := s.Poll(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkProcSource_Poll walks /proc directly. Linux-only, like
+// procSource itself.
+func BenchmarkProcSource_Poll(b *testing.B) {
+	ctx := context.Background()
+	s := newProcSource()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := s.Poll(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkGopsutilSource_Poll delegates to gopsutil, the cross-platform
+// backend.
+func BenchmarkGopsutilSource_Poll(b *testing.B) {
+	ctx := context.Background()
+	s := gopsutilSource{}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := s.Poll(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// errReplayDone is returned by replaySource.Poll once every recorded tick
+// has been played back, so callers can tell "the demo ended" apart from a
+// real polling failure and exit instead of retrying forever.
+var errReplayDone = errors.New("replay finished")
+
+// recordHeader is the first line of a --record file: the rng seed in use
+// at record time, so --replay can reproduce it and get byte-identical
+// frames out of pickLanes/spawnObstacles/the initial-pos roll.
+type recordHeader struct {
+	Seed int64 `json:"seed"`
+}
+
+// recordedTick is one newline-delimited JSON line after the header: a
+// tick's samples plus how long after record start it was polled, so
+// replaySource can reproduce the original cadence.
+type recordedTick struct {
+	TickNanos int64        `json:"tick_ns"`
+	Samples   []wireSample `json:"samples"`
+}
+
+// wireSample is processSample's JSON form. Kept as its own type rather
+// than tagging processSample directly so the on-disk format doesn't
+// shift just because an unexported struct field gets renamed.
+type wireSample struct {
+	PID          int     `json:"pid"`
+	CPU          float64 `json:"cpu"`
+	Mem          float64 `json:"mem"`
+	State        string  `json:"state"`
+	Command      string  `json:"command"`
+	Threads      int     `json:"threads"`
+	IOReadBytes  uint64  `json:"io_read_bytes"`
+	IOWriteBytes uint64  `json:"io_write_bytes"`
+}
+
+func toWire(samples []processSample) []wireSample {
+	out := make([]wireSample, len(samples))
+	for i, s := range samples {
+		out[i] = wireSample{
+			PID:          s.pid,
+			CPU:          s.cpu,
+			Mem:          s.mem,
+			State:        s.state,
+			Command:      s.command,
+			Threads:      s.threads,
+			IOReadBytes:  s.ioReadBytes,
+			IOWriteBytes: s.ioWriteBytes,
+		}
+	}
+	return out
+}
+
+func fromWire(samples []wireSample) []processSample {
+	out := make([]processSample, len(samples))
+	for i, s := range samples {
+		out[i] = processSample{
+			pid:          s.PID,
+			cpu:          s.CPU,
+			mem:          s.Mem,
+			state:        s.State,
+			command:      s.Command,
+			threads:      s.Threads,
+			ioReadBytes:  s.IOReadBytes,
+			ioWriteBytes: s.IOWriteBytes,
+		}
+	}
+	return out
+}
+
+// recordingSource wraps another ProcessSource and mirrors every sample
+// it returns to a newline-delimited JSON file before handing it back, so
+// the tick is captured exactly as render will have seen it.
+type recordingSource struct {
+	inner ProcessSource
+	enc   *json.Encoder
+	start time.Time
+}
+
+// newRecordingSource creates path and writes the header line up front.
+// The returned close func flushes and closes the file; callers must
+// defer it so the last few ticks aren't lost on exit.
+func newRecordingSource(inner ProcessSource, path string, seed int64) (*recordingSource, func() error, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(recordHeader{Seed: seed}); err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	rs := &recordingSource{inner: inner, enc: enc, start: time.Now()}
+	return rs, func() error {
+		if err := w.Flush(); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}, nil
+}
+
+func (r *recordingSource) Poll(ctx context.Context) ([]processSample, error) {
+	samples, err := r.inner.Poll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_ = r.enc.Encode(recordedTick{
+		TickNanos: time.Since(r.start).Nanoseconds(),
+		Samples:   toWire(samples),
+	})
+	return samples, nil
+}
+
+// replaySource plays a --record file back tick by tick at the cadence it
+// was captured with, instead of touching the real process table.
+type replaySource struct {
+	ticks []recordedTick
+	idx   int
+	start time.Time
+}
+
+// newReplaySource reads the whole file up front (these are meant for
+// demos and regression fixtures, not multi-gigabyte traces) and returns
+// the seed from its header alongside the source.
+func newReplaySource(path string) (*replaySource, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4<<20)
+
+	if !scanner.Scan() {
+		return nil, 0, fmt.Errorf("replay file %s is empty", path)
+	}
+	var header recordHeader
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return nil, 0, fmt.Errorf("replay header: %w", err)
+	}
+
+	var ticks []recordedTick
+	for scanner.Scan() {
+		var t recordedTick
+		if err := json.Unmarshal(scanner.Bytes(), &t); err != nil {
+			return nil, 0, fmt.Errorf("replay tick %d: %w", len(ticks), err)
+		}
+		ticks = append(ticks, t)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return &replaySource{ticks: ticks}, header.Seed, nil
+}
+
+func (r *replaySource) Poll(ctx context.Context) ([]processSample, error) {
+	if r.idx >= len(r.ticks) {
+		return nil, errReplayDone
+	}
+	if r.idx == 0 {
+		r.start = time.Now()
+	}
+
+	t := r.ticks[r.idx]
+	r.idx++
+
+	if wait := time.Until(r.start.Add(time.Duration(t.TickNanos))); wait > 0 {
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return fromWire(t.Samples), nil
+}
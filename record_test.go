@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func sampleSamples() []processSample {
+	return []processSample{
+		{pid: 1, cpu: 12.5, mem: 3.25, state: "S", command: "init", threads: 1, ioReadBytes: 100, ioWriteBytes: 0},
+		{pid: 2, cpu: 0, mem: 0, state: "Z", command: "zombie-kid", threads: 1, ioReadBytes: 0, ioWriteBytes: 0},
+	}
+}
+
+// fakeSource replays a fixed sequence of samples/errors, one per Poll
+// call, so recordingSource can be driven deterministically in a test.
+type fakeSource struct {
+	ticks [][]processSample
+	idx   int
+}
+
+func (f *fakeSource) Poll(ctx context.Context) ([]processSample, error) {
+	if f.idx >= len(f.ticks) {
+		return nil, errors.New("fakeSource exhausted")
+	}
+	s := f.ticks[f.idx]
+	f.idx++
+	return s, nil
+}
+
+func TestToWireFromWireRoundTrip(t *testing.T) {
+	want := sampleSamples()
+	got := fromWire(toWire(want))
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d samples, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("sample %d round-tripped as %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRecordAndReplayRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "demo.ndjson")
+	inner := &fakeSource{ticks: [][]processSample{sampleSamples(), {}}}
+
+	const seed = int64(42)
+	rs, closeFn, err := newRecordingSource(inner, path, seed)
+	if err != nil {
+		t.Fatalf("newRecordingSource: %v", err)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < len(inner.ticks); i++ {
+		if _, err := rs.Poll(ctx); err != nil {
+			t.Fatalf("recordingSource.Poll: %v", err)
+		}
+	}
+	if err := closeFn(); err != nil {
+		t.Fatalf("closing recording: %v", err)
+	}
+
+	replay, gotSeed, err := newReplaySource(path)
+	if err != nil {
+		t.Fatalf("newReplaySource: %v", err)
+	}
+	if gotSeed != seed {
+		t.Fatalf("replay seed = %d, want %d", gotSeed, seed)
+	}
+
+	first, err := replay.Poll(ctx)
+	if err != nil {
+		t.Fatalf("replay.Poll (tick 0): %v", err)
+	}
+	if len(first) != len(sampleSamples()) {
+		t.Fatalf("replay tick 0 returned %d samples, want %d", len(first), len(sampleSamples()))
+	}
+	for i, want := range sampleSamples() {
+		if first[i] != want {
+			t.Fatalf("replay tick 0 sample %d = %+v, want %+v", i, first[i], want)
+		}
+	}
+
+	second, err := replay.Poll(ctx)
+	if err != nil {
+		t.Fatalf("replay.Poll (tick 1): %v", err)
+	}
+	if len(second) != 0 {
+		t.Fatalf("replay tick 1 returned %d samples, want 0", len(second))
+	}
+}
+
+func TestReplaySourceDoneAfterLastTick(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "demo.ndjson")
+	inner := &fakeSource{ticks: [][]processSample{sampleSamples()}}
+
+	rs, closeFn, err := newRecordingSource(inner, path, 7)
+	if err != nil {
+		t.Fatalf("newRecordingSource: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := rs.Poll(ctx); err != nil {
+		t.Fatalf("recordingSource.Poll: %v", err)
+	}
+	if err := closeFn(); err != nil {
+		t.Fatalf("closing recording: %v", err)
+	}
+
+	replay, _, err := newReplaySource(path)
+	if err != nil {
+		t.Fatalf("newReplaySource: %v", err)
+	}
+	if _, err := replay.Poll(ctx); err != nil {
+		t.Fatalf("replay.Poll (only tick): %v", err)
+	}
+	if _, err := replay.Poll(ctx); !errors.Is(err, errReplayDone) {
+		t.Fatalf("replay.Poll past the last tick = %v, want errReplayDone", err)
+	}
+}
+
+func TestNewReplaySourceEmptyFileErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.ndjson")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatalf("writing empty file: %v", err)
+	}
+	if _, _, err := newReplaySource(path); err == nil {
+		t.Fatal("newReplaySource on an empty file: want error, got nil")
+	}
+}
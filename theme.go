@@ -0,0 +1,166 @@
+package main
+
+// Theme supplies the glyphs render draws for lane chrome (walls, finish
+// line, dashes, obstacles) and the "running" sprite pickSprite falls
+// back to once emoji mode and the special dead/zombie/pit frames are
+// ruled out. Keeping this behind an interface is what lets --theme swap
+// between plain ASCII, UTF-8 box-drawing and the old wide retro car
+// without render caring which one it got.
+type Theme interface {
+	Name() string
+	Wall() string
+	Finish() string
+	Dash() string
+	Obstacle() string
+	Car(r *runner) []string
+}
+
+func themeByName(name string) Theme {
+	switch name {
+	case "ascii":
+		return asciiTheme{}
+	case "retro":
+		return retroTheme{}
+	case "unicode", "":
+		return unicodeTheme{}
+	default:
+		return unicodeTheme{}
+	}
+}
+
+// asciiTheme is the safe fallback for terminals/fonts that can't be
+// trusted with box-drawing or emoji: plain `|`/`-`/`#` chrome and a short
+// bracket-and-arrow car.
+type asciiTheme struct{}
+
+func (asciiTheme) Name() string     { return "ascii" }
+func (asciiTheme) Wall() string     { return "|" }
+func (asciiTheme) Finish() string   { return "|" }
+func (asciiTheme) Dash() string     { return "-" }
+func (asciiTheme) Obstacle() string { return "#" }
+
+func (asciiTheme) Car(r *runner) []string {
+	car := "<=>"
+	if r.mem > 12 {
+		car = "[P]" + car
+	} else if r.mem > 6 {
+		car = "[+]" + car
+	}
+	if r.maluca {
+		car = "[CHR]" + car
+	}
+	if r.cpu > 70 {
+		car += ">>"
+	}
+	return []string{car}
+}
+
+// unicodeTheme is the default: box-drawing chrome and a little two-line
+// car built from the same corner/shade glyphs as the walls.
+type unicodeTheme struct{}
+
+func (unicodeTheme) Name() string     { return "unicode" }
+func (unicodeTheme) Wall() string     { return "│" } // │
+func (unicodeTheme) Finish() string   { return "│" } // │ (attrReverse sets it apart)
+func (unicodeTheme) Dash() string     { return "─" } // ─
+func (unicodeTheme) Obstacle() string { return "▓" } // ▓
+
+func (unicodeTheme) Car(r *runner) []string {
+	hood := "╭──╮" // ╭──╮
+	belt := "╰──╯" // ╰──╯
+	if r.mem > 12 {
+		hood = "[P]" + hood
+	} else if r.mem > 6 {
+		hood = "[+]" + hood
+	}
+	if r.maluca {
+		hood = "[CHR]" + hood
+	}
+	if r.cpu > 70 {
+		belt += "░░" // ░░ exhaust
+	}
+	return []string{hood, belt}
+}
+
+// retroTheme keeps the original wide ASCII car around for anyone who
+// grew attached to it (or scripts that grep for it), warts and all.
+type retroTheme struct{}
+
+func (retroTheme) Name() string     { return "retro" }
+func (retroTheme) Wall() string     { return "|" }
+func (retroTheme) Finish() string   { return "|" }
+func (retroTheme) Dash() string     { return "-" }
+func (retroTheme) Obstacle() string { return "#" }
+
+func (retroTheme) Car(r *runner) []string {
+	car := []string{
+		"___      ___        ::::::::::::::::::::::::       [_ _]    [_ _]   _",
+		"  /|  ___$________S_   | \\",
+		" / |-/        ____  [++| |+",
+		"<<<<<---<|  |>____O)<ooo>|",
+		" \\ |-\\___ ________ _[++| |+",
+		"  \\|    _$_      _S_   |_/  ",
+		"       [___]    [___]",
+	}
+	if r.mem > 12 {
+		car[0] = "[P]" + car[0]
+	} else if r.mem > 6 {
+		car[0] = "[+]" + car[0]
+	}
+	if r.maluca {
+		car[0] = "[CHR]" + car[0]
+	}
+	if r.cpu > 70 {
+		car[len(car)-1] = car[len(car)-1] + ">>"
+	}
+	return car
+}
+
+// cell is one terminal column of a track row: a glyph plus the color/
+// attrs it should be drawn with. Keeping color separate from the glyph
+// (rather than pre-wrapping it in ANSI codes, like the old []byte track
+// buffer did) is what makes it safe to place multi-byte UTF-8 glyphs
+// without splitting an escape sequence across cells.
+type cell struct {
+	glyph string
+	color string
+	attrs []string
+}
+
+// newRow builds a blank track row of width cells for render to paint
+// dashes, walls and the car into.
+func newRow(width int) []cell {
+	row := make([]cell, width)
+	for i := range row {
+		row[i] = cell{glyph: " "}
+	}
+	return row
+}
+
+// renderRow flattens a row of cells into the final escape-coded string.
+func renderRow(row []cell) string {
+	var b []byte
+	for _, c := range row {
+		b = append(b, colorize(c.glyph, c.color, c.attrs...)...)
+	}
+	return string(b)
+}
+
+// putSpriteCells writes sprite's runes into row starting at pos, one
+// rune per cell, each carrying color and attrs. pos is clamped into
+// range the same way the old byte-oriented putSpriteLine was.
+func putSpriteCells(row []cell, pos int, sprite, color string, attrs []string) {
+	if pos < 0 {
+		pos = 0
+	}
+	if pos >= len(row) {
+		pos = len(row) - 1
+	}
+	for i, r := range []rune(sprite) {
+		idx := pos + i
+		if idx < 0 || idx >= len(row) {
+			break
+		}
+		row[idx] = cell{glyph: string(r), color: color, attrs: attrs}
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// goldenLanes is a small, fixed set of runners covering the statuses
+// pickSprite branches on (running, dead, zombie, pit), so a themed golden
+// frame exercises every sprite path in one shot.
+func goldenLanes() []*runner {
+	return []*runner{
+		{pid: 100, name: "gopher", cpu: 42.5, mem: 8.1, status: statusRunning, pos: 3},
+		{pid: 200, name: "chromium", cpu: 91.0, mem: 14.2, status: statusRunning, maluca: true, pos: 7},
+		{pid: 300, name: "idle-task", cpu: 0.2, mem: 1.0, status: statusRunning, pos: 0},
+		{pid: 400, name: "zombie-kid", cpu: 0, mem: 0, status: statusZombie, pos: 2},
+		{pid: 500, name: "dead-weight", cpu: 0, mem: 0, status: statusDead, pos: 1},
+		{pid: 600, name: "waiting-io", cpu: 5, mem: 3, status: statusPitStop, pos: 4},
+	}
+}
+
+func goldenFrame(themeName string) string {
+	cfg := options{
+		maxLanes:   len(goldenLanes()),
+		malucaMode: true,
+		useEmoji:   false,
+		theme:      themeByName(themeName),
+	}
+	var ctl controller
+	ctl.cursor = 1
+	var ui uiState
+	obstacles := map[int]struct{}{8: {}}
+	return renderFrame(goldenLanes(), obstacles, 40, 0, cfg, &ctl, &ui)
+}
+
+func TestRenderFrameGolden(t *testing.T) {
+	for _, name := range []string{"ascii", "unicode", "retro"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			got := goldenFrame(name)
+			path := filepath.Join("testdata", "golden_"+name+".txt")
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("reading golden file: %v", err)
+			}
+			if got != string(want) {
+				t.Fatalf("renderFrame(%q) doesn't match %s\n--- got ---\n%s\n--- want ---\n%s", name, path, got, string(want))
+			}
+		})
+	}
+}